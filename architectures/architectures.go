@@ -0,0 +1,107 @@
+// Package architectures builds ready-to-train nn.Sequential topologies so
+// main doesn't have to hand-assemble layers for every experiment.
+package architectures
+
+import (
+	"math/rand"
+
+	"github.com/weswest/msds431wk10/nn"
+)
+
+// BuildCNN returns the original conv-pool-conv-pool-dense-dense topology,
+// sized for a single-channel inputSize x inputSize image, with weights
+// drawn from rng.
+func BuildCNN(inputSize int, rng *rand.Rand) *nn.Sequential {
+	conv1 := nn.NewConv2D(1, 32, 3, 1, 0, rng)
+	pool1 := nn.NewMaxPool2D(2, 2)
+	conv2 := nn.NewConv2D(32, 64, 3, 1, 0, rng)
+	pool2 := nn.NewMaxPool2D(2, 2)
+
+	size := poolOut(convOut(poolOut(convOut(inputSize, 3, 1, 0), 2, 2), 3, 1, 0), 2, 2)
+	features := size * size * 64
+
+	fc1 := nn.NewDense(features, 128, rng)
+	fc2 := nn.NewDense(128, 10, rng)
+
+	return nn.NewSequential(
+		conv1, nn.NewReLU(), pool1,
+		conv2, nn.NewReLU(), pool2,
+		fc1, nn.NewSigmoid(),
+		fc2,
+	)
+}
+
+// BuildLeNet5 returns the classic LeNet-5 topology (conv-pool-conv-pool
+// then three dense layers) sized for a single-channel inputSize x inputSize
+// image, with weights drawn from rng.
+func BuildLeNet5(inputSize int, rng *rand.Rand) *nn.Sequential {
+	conv1 := nn.NewConv2D(1, 6, 5, 1, 0, rng)
+	pool1 := nn.NewMaxPool2D(2, 2)
+	conv2 := nn.NewConv2D(6, 16, 5, 1, 0, rng)
+	pool2 := nn.NewMaxPool2D(2, 2)
+
+	size := poolOut(convOut(poolOut(convOut(inputSize, 5, 1, 0), 2, 2), 5, 1, 0), 2, 2)
+	features := size * size * 16
+
+	fc1 := nn.NewDense(features, 120, rng)
+	fc2 := nn.NewDense(120, 84, rng)
+	fc3 := nn.NewDense(84, 10, rng)
+
+	return nn.NewSequential(
+		conv1, nn.NewReLU(), pool1,
+		conv2, nn.NewReLU(), pool2,
+		fc1, nn.NewSigmoid(),
+		fc2, nn.NewSigmoid(),
+		fc3,
+	)
+}
+
+// BuildVGGMini returns a deeper conv-heavy topology in the style of VGG:
+// two 3x3 convolutions per stage before each pool, doubling filter count
+// each stage, followed by a dropout-regularized dense head, with weights
+// drawn from rng.
+func BuildVGGMini(inputSize int, rng *rand.Rand) *nn.Sequential {
+	var layerList []nn.Layer
+	size := inputSize
+	inC := 1
+	for _, outC := range []int{32, 64} {
+		layerList = append(layerList,
+			nn.NewConv2D(inC, outC, 3, 1, 1, rng), nn.NewReLU(),
+			nn.NewConv2D(outC, outC, 3, 1, 1, rng), nn.NewReLU(),
+			nn.NewMaxPool2D(2, 2),
+		)
+		size = poolOut(size, 2, 2)
+		inC = outC
+	}
+
+	features := size * size * inC
+	fc1 := nn.NewDense(features, 256, rng)
+	fc2 := nn.NewDense(256, 10, rng)
+	layerList = append(layerList, fc1, nn.NewSigmoid(), nn.NewDropout(0.5, rng), fc2)
+
+	return nn.NewSequential(layerList...)
+}
+
+// BuildMiniResNet stacks numBlocks real ResNet basic blocks (ConvBN-ReLU-
+// ConvBN with the block's input summed back in before the final ReLU)
+// ahead of a single dense classifier head, with weights drawn from rng.
+func BuildMiniResNet(inputSize int, numBlocks int, rng *rand.Rand) *nn.Sequential {
+	channels := 16
+	layerList := []nn.Layer{
+		nn.NewConv2D(1, channels, 3, 1, 1, rng),
+		nn.NewBatchNorm(channels, 0.9, 1e-5),
+		nn.NewReLU(),
+	}
+	for i := 0; i < numBlocks; i++ {
+		layerList = append(layerList, nn.NewResidualBlock(channels, rng))
+	}
+
+	features := inputSize * inputSize * channels
+	fc := nn.NewDense(features, 10, rng)
+	layerList = append(layerList, fc)
+
+	return nn.NewSequential(layerList...)
+}
+
+func convOut(in, k, stride, pad int) int { return (in+2*pad-k)/stride + 1 }
+func poolOut(in, k, stride int) int      { return (in-k)/stride + 1 }
@@ -0,0 +1,46 @@
+package architectures
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/weswest/msds431wk10/nn"
+)
+
+// TestBuildersForwardWithoutPanic runs a single forward pass through each
+// builder's topology and checks the output lands on the expected 10-class
+// shape, catching a dimension mismatch between a builder's conv/pool
+// stack and its dense head before it surfaces as a runtime panic during
+// training.
+func TestBuildersForwardWithoutPanic(t *testing.T) {
+	const inputSize = 28
+	rng := rand.New(rand.NewSource(1))
+
+	builders := map[string]*nn.Sequential{
+		"CNN":      BuildCNN(inputSize, rng),
+		"LeNet5":   BuildLeNet5(inputSize, rng),
+		"VGGMini":  BuildVGGMini(inputSize, rng),
+		"ResNet-2": BuildMiniResNet(inputSize, 2, rng),
+	}
+
+	for name, body := range builders {
+		body := body
+		t.Run(name, func(t *testing.T) {
+			x := nn.NewBatch(1, 1, inputSize, inputSize)
+			for i := range x.Data {
+				x.Data[i] = rng.Float64()
+			}
+
+			out := body.Forward(x)
+			if out.C != 10 || out.H != 1 || out.W != 1 {
+				t.Fatalf("%s: Forward output shape = (C=%d, H=%d, W=%d), want (C=10, H=1, W=1)", name, out.C, out.H, out.W)
+			}
+
+			grad := nn.NewBatch(out.N, out.C, out.H, out.W)
+			for i := range grad.Data {
+				grad.Data[i] = 1
+			}
+			body.Backward(grad)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package augment provides composable image transforms for MNIST-shaped
+// (28x28x1) tensors, used to diversify training batches on the fly.
+package augment
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/LdDl/cnns/tensor"
+)
+
+// Transform maps one tensor to another, e.g. a random perturbation or a
+// normalization step.
+type Transform func(t *tensor.Tensor) *tensor.Tensor
+
+// Compose chains transforms left to right into a single Transform.
+func Compose(transforms ...Transform) Transform {
+	return func(t *tensor.Tensor) *tensor.Tensor {
+		for _, transform := range transforms {
+			t = transform(t)
+		}
+		return t
+	}
+}
+
+// fromData builds a tensor.Tensor shaped like t with out as its backing
+// data (tensor.Tensor's constructor only takes dimensions, not a backing
+// slice).
+func fromData(t *tensor.Tensor, out []float64) *tensor.Tensor {
+	result := tensor.NewTensor(t.Size.X, t.Size.Y, t.Size.Z)
+	result.Data = out
+	return result
+}
+
+// Normalize rescales every pixel to (x-mean)/std.
+func Normalize(mean, std float64) Transform {
+	return func(t *tensor.Tensor) *tensor.Tensor {
+		out := make([]float64, len(t.Data))
+		for i, v := range t.Data {
+			out[i] = (v - mean) / std
+		}
+		return fromData(t, out)
+	}
+}
+
+// RandomShift translates the image by up to maxPx pixels in each axis,
+// drawn independently per call, with zero padding at the edges revealed
+// by the shift.
+func RandomShift(maxPx int, rng *rand.Rand) Transform {
+	return func(t *tensor.Tensor) *tensor.Tensor {
+		dx := rng.Intn(2*maxPx+1) - maxPx
+		dy := rng.Intn(2*maxPx+1) - maxPx
+
+		width, height := t.Size.X, t.Size.Y
+		out := make([]float64, len(t.Data))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				srcX, srcY := x-dx, y-dy
+				if srcX >= 0 && srcX < width && srcY >= 0 && srcY < height {
+					out[y*width+x] = t.Data[srcY*width+srcX]
+				}
+			}
+		}
+		return fromData(t, out)
+	}
+}
+
+// RandomRotate rotates the image by up to maxDeg degrees (in either
+// direction) about its center, resampling with bilinear interpolation.
+func RandomRotate(maxDeg float64, rng *rand.Rand) Transform {
+	return func(t *tensor.Tensor) *tensor.Tensor {
+		deg := (rng.Float64()*2 - 1) * maxDeg
+		angle := deg * math.Pi / 180
+
+		width, height := t.Size.X, t.Size.Y
+		cx, cy := float64(width-1)/2, float64(height-1)/2
+		cosA, sinA := math.Cos(angle), math.Sin(angle)
+
+		out := make([]float64, len(t.Data))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dx, dy := float64(x)-cx, float64(y)-cy
+				srcX := cosA*dx + sinA*dy + cx
+				srcY := -sinA*dx + cosA*dy + cy
+				out[y*width+x] = bilinear(t.Data, width, height, srcX, srcY)
+			}
+		}
+		return fromData(t, out)
+	}
+}
+
+// RandomElasticDeform applies the classic MNIST elastic distortion: a
+// per-pixel random displacement field, smoothed by a Gaussian of stddev
+// sigma and scaled by alpha, sampled back into the image with bilinear
+// interpolation.
+func RandomElasticDeform(alpha, sigma float64, rng *rand.Rand) Transform {
+	return func(t *tensor.Tensor) *tensor.Tensor {
+		width, height := t.Size.X, t.Size.Y
+
+		dxField := gaussianBlur(randomField(width, height, rng), width, height, sigma)
+		dyField := gaussianBlur(randomField(width, height, rng), width, height, sigma)
+
+		out := make([]float64, len(t.Data))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				idx := y*width + x
+				srcX := float64(x) + alpha*dxField[idx]
+				srcY := float64(y) + alpha*dyField[idx]
+				out[idx] = bilinear(t.Data, width, height, srcX, srcY)
+			}
+		}
+		return fromData(t, out)
+	}
+}
+
+func randomField(width, height int, rng *rand.Rand) []float64 {
+	field := make([]float64, width*height)
+	for i := range field {
+		field[i] = rng.Float64()*2 - 1
+	}
+	return field
+}
+
+// gaussianBlur smooths field with a separable 1D Gaussian kernel of the
+// given stddev, zero-padding outside the field's bounds.
+func gaussianBlur(field []float64, width, height int, sigma float64) []float64 {
+	kernel := gaussianKernel1D(sigma)
+	radius := len(kernel) / 2
+
+	horiz := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sum := 0.0
+			for k := -radius; k <= radius; k++ {
+				xi := x + k
+				if xi < 0 || xi >= width {
+					continue
+				}
+				sum += field[y*width+xi] * kernel[k+radius]
+			}
+			horiz[y*width+x] = sum
+		}
+	}
+
+	out := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sum := 0.0
+			for k := -radius; k <= radius; k++ {
+				yi := y + k
+				if yi < 0 || yi >= height {
+					continue
+				}
+				sum += horiz[yi*width+x] * kernel[k+radius]
+			}
+			out[y*width+x] = sum
+		}
+	}
+	return out
+}
+
+func gaussianKernel1D(sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	if radius < 1 {
+		radius = 1
+	}
+
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := -radius; i <= radius; i++ {
+		v := math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		kernel[i+radius] = v
+		sum += v
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// bilinear samples data (a width x height grid) at the fractional
+// coordinate (x, y), treating anything outside the grid as zero.
+func bilinear(data []float64, width, height int, x, y float64) float64 {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	x1, y1 := x0+1, y0+1
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	get := func(xi, yi int) float64 {
+		if xi < 0 || xi >= width || yi < 0 || yi >= height {
+			return 0
+		}
+		return data[yi*width+xi]
+	}
+
+	top := get(x0, y0)*(1-fx) + get(x1, y0)*fx
+	bottom := get(x0, y1)*(1-fx) + get(x1, y1)*fx
+	return top*(1-fy) + bottom*fy
+}
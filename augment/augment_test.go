@@ -0,0 +1,104 @@
+package augment
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/LdDl/cnns/tensor"
+)
+
+func newTestImage() *tensor.Tensor {
+	t := tensor.NewTensor(28, 28, 1)
+	for i := range t.Data {
+		t.Data[i] = float64(i) / float64(len(t.Data))
+	}
+	return t
+}
+
+// TestRandomRotateShapeAndDeterminism checks that RandomRotate preserves
+// the image's dimensions and that two transforms seeded identically
+// produce identical output, since the augmentation is meant to be
+// reproducible per-rng rather than globally random.
+func TestRandomRotateShapeAndDeterminism(t *testing.T) {
+	img := newTestImage()
+
+	rotateA := RandomRotate(15, rand.New(rand.NewSource(1)))
+	rotateB := RandomRotate(15, rand.New(rand.NewSource(1)))
+
+	outA := rotateA(img)
+	outB := rotateB(img)
+
+	if outA.Size.X != img.Size.X || outA.Size.Y != img.Size.Y || outA.Size.Z != img.Size.Z {
+		t.Fatalf("RandomRotate changed shape: got %+v, want %+v", outA.Size, img.Size)
+	}
+	if len(outA.Data) != len(img.Data) {
+		t.Fatalf("RandomRotate changed data length: got %d, want %d", len(outA.Data), len(img.Data))
+	}
+	for i := range outA.Data {
+		if outA.Data[i] != outB.Data[i] {
+			t.Fatalf("same seed produced different output at %d: %v vs %v", i, outA.Data[i], outB.Data[i])
+		}
+	}
+
+	// A zero-degree rotation is the identity, modulo floating point.
+	identity := RandomRotate(0, rand.New(rand.NewSource(2)))(img)
+	for i := range identity.Data {
+		if diff := identity.Data[i] - img.Data[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("zero-degree rotation changed pixel %d: got %v, want %v", i, identity.Data[i], img.Data[i])
+		}
+	}
+}
+
+// TestRandomElasticDeformShapeAndDeterminism mirrors
+// TestRandomRotateShapeAndDeterminism for the elastic deform transform,
+// the most numerically involved one in the package (random field ->
+// Gaussian blur -> bilinear resample).
+func TestRandomElasticDeformShapeAndDeterminism(t *testing.T) {
+	img := newTestImage()
+
+	deformA := RandomElasticDeform(8, 4, rand.New(rand.NewSource(3)))
+	deformB := RandomElasticDeform(8, 4, rand.New(rand.NewSource(3)))
+
+	outA := deformA(img)
+	outB := deformB(img)
+
+	if outA.Size.X != img.Size.X || outA.Size.Y != img.Size.Y || outA.Size.Z != img.Size.Z {
+		t.Fatalf("RandomElasticDeform changed shape: got %+v, want %+v", outA.Size, img.Size)
+	}
+	for i := range outA.Data {
+		if outA.Data[i] != outB.Data[i] {
+			t.Fatalf("same seed produced different output at %d: %v vs %v", i, outA.Data[i], outB.Data[i])
+		}
+	}
+
+	// Zero alpha means no displacement, so the output should equal the input.
+	identity := RandomElasticDeform(0, 4, rand.New(rand.NewSource(4)))(img)
+	for i := range identity.Data {
+		if diff := identity.Data[i] - img.Data[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("zero-alpha deform changed pixel %d: got %v, want %v", i, identity.Data[i], img.Data[i])
+		}
+	}
+}
+
+// TestBilinearKnownPoints checks bilinear against hand-computed values: an
+// exact grid point returns that pixel, the midpoint of two pixels returns
+// their average, and a point outside the grid returns zero padding.
+func TestBilinearKnownPoints(t *testing.T) {
+	data := []float64{
+		0, 10,
+		20, 30,
+	}
+
+	if got := bilinear(data, 2, 2, 0, 0); got != 0 {
+		t.Fatalf("bilinear(0,0) = %v, want 0", got)
+	}
+	if got := bilinear(data, 2, 2, 1, 1); got != 30 {
+		t.Fatalf("bilinear(1,1) = %v, want 30", got)
+	}
+	if got, want := bilinear(data, 2, 2, 0.5, 0), 5.0; got != want {
+		t.Fatalf("bilinear(0.5,0) = %v, want %v", got, want)
+	}
+	if got := bilinear(data, 2, 2, -1, -1); got != 0 {
+		t.Fatalf("bilinear(-1,-1) = %v, want 0 (out of bounds)", got)
+	}
+}
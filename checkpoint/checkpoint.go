@@ -0,0 +1,364 @@
+// Package checkpoint persists an nn.Sequential's architecture and learned
+// weights to disk in an IDX-style binary layout plus a JSON manifest, so a
+// run can be resumed or scored with its trained parameters intact.
+//
+// Each layer's Params() are written out, in the order Params() returns
+// them, as a flat run of little-endian float64s immediately after that
+// layer's topology record; WeightCount records how many floats to expect.
+// LoadCheckpoint rebuilds each layer from the manifest (which seeds it with
+// freshly initialized weights) and then overwrites those weights in place
+// from the saved floats, so a reloaded net's Params() match the net that
+// was saved, not a random reinitialization.
+package checkpoint
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/weswest/msds431wk10/nn"
+)
+
+// Magic identifies a checkpoint binary file.
+const Magic uint32 = 0x434E4E00
+
+// LayerManifest describes one layer's kind and the configuration needed to
+// reconstruct it (e.g. a conv2d's channel/kernel/stride/pad, or a dropout's
+// drop probability). What Ints/Floats hold, and in what order, is defined
+// per kind in init() below.
+type LayerManifest struct {
+	Kind        string    `json:"kind"`
+	Ints        []int     `json:"ints,omitempty"`
+	Floats      []float64 `json:"floats,omitempty"`
+	WeightCount uint64    `json:"weight_count"`
+}
+
+// Manifest is the sidecar JSON describing a checkpoint's layers.
+type Manifest struct {
+	LayerCount int             `json:"layer_count"`
+	Layers     []LayerManifest `json:"layers"`
+}
+
+// ctor reconstructs a layer from its manifest entry's Ints/Floats. rng seeds
+// any weights the layer is initialized with.
+type ctor func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error)
+
+var registry = map[string]ctor{}
+
+// register adds a layer kind to the registry LoadCheckpoint uses to
+// reconstruct a Sequential's Layers slice.
+func register(kind string, fn ctor) {
+	registry[kind] = fn
+}
+
+func init() {
+	register("conv2d", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(ints) != 5 {
+			return nil, fmt.Errorf("checkpoint: conv2d wants 5 ints, got %d", len(ints))
+		}
+		return nn.NewConv2D(ints[0], ints[1], ints[2], ints[3], ints[4], rng), nil
+	})
+	register("relu", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		return nn.NewReLU(), nil
+	})
+	register("maxpool2d", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(ints) != 2 {
+			return nil, fmt.Errorf("checkpoint: maxpool2d wants 2 ints, got %d", len(ints))
+		}
+		return nn.NewMaxPool2D(ints[0], ints[1]), nil
+	})
+	register("dense", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(ints) != 2 {
+			return nil, fmt.Errorf("checkpoint: dense wants 2 ints, got %d", len(ints))
+		}
+		return nn.NewDense(ints[0], ints[1], rng), nil
+	})
+	register("sigmoid", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		return nn.NewSigmoid(), nil
+	})
+	register("batchnorm", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(ints) != 1 {
+			return nil, fmt.Errorf("checkpoint: batchnorm wants 1 int, got %d", len(ints))
+		}
+		bn := nn.NewBatchNorm(ints[0], 0, 0)
+		if err := decodeBatchNormStats(bn, floats); err != nil {
+			return nil, err
+		}
+		return bn, nil
+	})
+	register("dropout", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(floats) != 1 {
+			return nil, fmt.Errorf("checkpoint: dropout wants 1 float, got %d", len(floats))
+		}
+		return nn.NewDropout(floats[0], rng), nil
+	})
+	register("resblock", func(ints []int, floats []float64, rng *rand.Rand) (nn.Layer, error) {
+		if len(ints) != 1 {
+			return nil, fmt.Errorf("checkpoint: resblock wants 1 int, got %d", len(ints))
+		}
+		block := nn.NewResidualBlock(ints[0], rng)
+		c := ints[0]
+		if len(floats) != 2*batchNormStatsLen(c) {
+			return nil, fmt.Errorf("checkpoint: resblock wants %d floats, got %d", 2*batchNormStatsLen(c), len(floats))
+		}
+		if err := decodeBatchNormStats(block.BN1, floats[:batchNormStatsLen(c)]); err != nil {
+			return nil, err
+		}
+		if err := decodeBatchNormStats(block.BN2, floats[batchNormStatsLen(c):]); err != nil {
+			return nil, err
+		}
+		return block, nil
+	})
+}
+
+// batchNormStatsLen returns how many floats encodeBatchNormStats produces
+// for a BatchNorm over c channels: momentum, eps, then c running means and
+// c running variances.
+func batchNormStatsLen(c int) int { return 2 + 2*c }
+
+// encodeBatchNormStats packs a BatchNorm's momentum/eps and running
+// mean/variance (its state beyond Gamma/Beta, which ride along as normal
+// Params) into a flat float slice for the manifest.
+func encodeBatchNormStats(bn *nn.BatchNorm) []float64 {
+	floats := make([]float64, 0, batchNormStatsLen(bn.C))
+	floats = append(floats, bn.Momentum, bn.Eps)
+	floats = append(floats, bn.RunningMean...)
+	floats = append(floats, bn.RunningVar...)
+	return floats
+}
+
+// decodeBatchNormStats is encodeBatchNormStats's inverse: it restores bn's
+// momentum/eps and running mean/variance from floats in place.
+func decodeBatchNormStats(bn *nn.BatchNorm, floats []float64) error {
+	if len(floats) != batchNormStatsLen(bn.C) {
+		return fmt.Errorf("checkpoint: batchnorm wants %d floats, got %d", batchNormStatsLen(bn.C), len(floats))
+	}
+	bn.Momentum, bn.Eps = floats[0], floats[1]
+	copy(bn.RunningMean, floats[2:2+bn.C])
+	copy(bn.RunningVar, floats[2+bn.C:2+2*bn.C])
+	return nil
+}
+
+// kindOf returns the manifest kind and reconstruction Ints/Floats for l.
+func kindOf(l nn.Layer) (string, []int, []float64, error) {
+	switch t := l.(type) {
+	case *nn.Conv2D:
+		return "conv2d", []int{t.InC, t.OutC, t.K, t.Stride, t.Pad}, nil, nil
+	case *nn.ReLU:
+		return "relu", nil, nil, nil
+	case *nn.MaxPool2D:
+		return "maxpool2d", []int{t.K, t.Stride}, nil, nil
+	case *nn.Dense:
+		return "dense", []int{t.InFeatures, t.OutFeatures}, nil, nil
+	case *nn.Sigmoid:
+		return "sigmoid", nil, nil, nil
+	case *nn.BatchNorm:
+		return "batchnorm", []int{t.C}, encodeBatchNormStats(t), nil
+	case *nn.Dropout:
+		return "dropout", nil, []float64{t.P}, nil
+	case *nn.ResidualBlock:
+		floats := append(encodeBatchNormStats(t.BN1), encodeBatchNormStats(t.BN2)...)
+		return "resblock", []int{t.Conv1.InC}, floats, nil
+	default:
+		return "", nil, nil, fmt.Errorf("checkpoint: unsupported layer type %T", l)
+	}
+}
+
+// SaveCheckpoint writes net's architecture and weights to path (binary)
+// and a human-readable copy of the architecture to path+".json".
+func SaveCheckpoint(net *nn.Sequential, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := binary.Write(f, binary.LittleEndian, Magic); err != nil {
+		return err
+	}
+	if err := binary.Write(f, binary.LittleEndian, uint32(len(net.Layers))); err != nil {
+		return err
+	}
+
+	manifest := Manifest{LayerCount: len(net.Layers)}
+	for _, l := range net.Layers {
+		kind, ints, floats, err := kindOf(l)
+		if err != nil {
+			return err
+		}
+		weights := paramWeights(l)
+		entry := LayerManifest{Kind: kind, Ints: ints, Floats: floats, WeightCount: uint64(len(weights))}
+		manifest.Layers = append(manifest.Layers, entry)
+
+		if err := writeLayerRecord(f, entry); err != nil {
+			return err
+		}
+		for _, w := range weights {
+			if err := binary.Write(f, binary.LittleEndian, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	manifestFile, err := os.Create(path + ".json")
+	if err != nil {
+		return err
+	}
+	defer manifestFile.Close()
+
+	enc := json.NewEncoder(manifestFile)
+	enc.SetIndent("", "  ")
+	return enc.Encode(manifest)
+}
+
+// paramWeights concatenates l's Params() data, in the order Params()
+// returns them, into a single flat slice.
+func paramWeights(l nn.Layer) []float64 {
+	var weights []float64
+	for _, p := range l.Params() {
+		weights = append(weights, p.Data...)
+	}
+	return weights
+}
+
+// setParamWeights overwrites l's Params() data in place from weights, a
+// flat slice laid out the same way paramWeights produces it.
+func setParamWeights(l nn.Layer, weights []float64) error {
+	var offset int
+	for _, p := range l.Params() {
+		if offset+len(p.Data) > len(weights) {
+			return fmt.Errorf("checkpoint: weight data too short for %T", l)
+		}
+		copy(p.Data, weights[offset:offset+len(p.Data)])
+		offset += len(p.Data)
+	}
+	if offset != len(weights) {
+		return fmt.Errorf("checkpoint: %d leftover weight floats for %T", len(weights)-offset, l)
+	}
+	return nil
+}
+
+func writeLayerRecord(w io.Writer, m LayerManifest) error {
+	if err := binary.Write(w, binary.LittleEndian, int32(len(m.Kind))); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(m.Kind)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(m.Ints))); err != nil {
+		return err
+	}
+	for _, v := range m.Ints {
+		if err := binary.Write(w, binary.LittleEndian, int32(v)); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(m.Floats))); err != nil {
+		return err
+	}
+	for _, v := range m.Floats {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	return binary.Write(w, binary.LittleEndian, m.WeightCount)
+}
+
+// readLayerRecord reads back one layer record written by writeLayerRecord
+// (everything up to, but not including, its weight floats).
+func readLayerRecord(r io.Reader) (LayerManifest, error) {
+	var m LayerManifest
+
+	var kindLen int32
+	if err := binary.Read(r, binary.LittleEndian, &kindLen); err != nil {
+		return m, err
+	}
+	kindBytes := make([]byte, kindLen)
+	if _, err := io.ReadFull(r, kindBytes); err != nil {
+		return m, err
+	}
+	m.Kind = string(kindBytes)
+
+	var intCount int32
+	if err := binary.Read(r, binary.LittleEndian, &intCount); err != nil {
+		return m, err
+	}
+	m.Ints = make([]int, intCount)
+	for i := range m.Ints {
+		var v int32
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return m, err
+		}
+		m.Ints[i] = int(v)
+	}
+
+	var floatCount int32
+	if err := binary.Read(r, binary.LittleEndian, &floatCount); err != nil {
+		return m, err
+	}
+	m.Floats = make([]float64, floatCount)
+	if err := binary.Read(r, binary.LittleEndian, &m.Floats); err != nil {
+		return m, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &m.WeightCount); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// LoadCheckpoint reconstructs a Sequential's layer topology from path,
+// then restores each layer's trained weights from the floats saved
+// alongside it. The JSON sidecar at path+".json" is not consulted; it
+// exists only as a human-readable record of what was saved.
+func LoadCheckpoint(path string) (*nn.Sequential, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var magic uint32
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil {
+		return nil, err
+	}
+	if magic != Magic {
+		return nil, fmt.Errorf("checkpoint: %s is not a checkpoint file", path)
+	}
+	var layerCount uint32
+	if err := binary.Read(f, binary.LittleEndian, &layerCount); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	net := &nn.Sequential{}
+	for i := uint32(0); i < layerCount; i++ {
+		entry, err := readLayerRecord(f)
+		if err != nil {
+			return nil, err
+		}
+		build, ok := registry[entry.Kind]
+		if !ok {
+			return nil, fmt.Errorf("checkpoint: unknown layer kind %q", entry.Kind)
+		}
+		layer, err := build(entry.Ints, entry.Floats, rng)
+		if err != nil {
+			return nil, err
+		}
+
+		weights := make([]float64, entry.WeightCount)
+		if err := binary.Read(f, binary.LittleEndian, &weights); err != nil {
+			return nil, err
+		}
+		if err := setParamWeights(layer, weights); err != nil {
+			return nil, err
+		}
+
+		net.Layers = append(net.Layers, layer)
+	}
+
+	return net, nil
+}
@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/weswest/msds431wk10/nn"
+)
+
+// TestSaveLoadRoundTrip trains a small net for a few steps, saves it, reloads
+// it, and checks the reloaded net scores identically to the trained one -
+// catching a regression back to LoadCheckpoint silently handing back a
+// freshly initialized (untrained) network.
+func TestSaveLoadRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	body := nn.NewSequential(
+		nn.NewConv2D(1, 2, 3, 1, 1, rng),
+		nn.NewBatchNorm(2, 0.9, 1e-5),
+		nn.NewReLU(),
+		nn.NewDense(2*4*4, 3, rng),
+	)
+	net := nn.NewNet(body, nn.NewSoftmaxCrossEntropy())
+
+	x := nn.NewBatch(4, 1, 4, 4)
+	for i := range x.Data {
+		x.Data[i] = rng.Float64()
+	}
+	target := nn.NewBatch(4, 3, 1, 1)
+	for n := 0; n < 4; n++ {
+		target.Set(n, n%3, 0, 0, 1)
+	}
+
+	net.SetTraining(true)
+	for i := 0; i < 5; i++ {
+		net.TrainStep(x, target, 0.1)
+	}
+
+	path := filepath.Join(t.TempDir(), "net.ckpt")
+	if err := SaveCheckpoint(body, path); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	reloaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	reloadedNet := nn.NewNet(reloaded, nn.NewSoftmaxCrossEntropy())
+
+	net.SetTraining(false)
+	reloadedNet.SetTraining(false)
+	wantLoss, wantOut := net.Evaluate(x, target)
+	gotLoss, gotOut := reloadedNet.Evaluate(x, target)
+
+	if gotLoss != wantLoss {
+		t.Fatalf("reloaded loss = %v, want %v", gotLoss, wantLoss)
+	}
+	for i := range wantOut.Data {
+		if gotOut.Data[i] != wantOut.Data[i] {
+			t.Fatalf("reloaded output[%d] = %v, want %v", i, gotOut.Data[i], wantOut.Data[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package data provides mini-batch iteration helpers for training the CNN
+// over in-memory MNIST tensors.
+package data
+
+import (
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/LdDl/cnns/tensor"
+
+	"github.com/weswest/msds431wk10/augment"
+)
+
+// DataIter walks a dataset of flattened images and one-hot labels in
+// mini-batches of batchSize samples, stacking each batch into a single
+// *mat.Dense so a caller can hand it to the model one step at a time. If
+// transform is set, it is applied to each image as its batch is built, so
+// passing a fresh augment.Compose(...) pipeline re-augments every sample
+// on every pass.
+type DataIter struct {
+	images    []*tensor.Tensor
+	labels    []*mat.Dense
+	batchSize int
+	transform augment.Transform
+	order     []int
+	pos       int
+}
+
+// NewDataIter wraps images/labels into a DataIter that yields batchSize
+// samples per call to Next, applying transform (if non-nil) to each image
+// as it is batched.
+func NewDataIter(images []*tensor.Tensor, labels []*mat.Dense, batchSize int, transform augment.Transform) *DataIter {
+	order := make([]int, len(images))
+	for i := range order {
+		order[i] = i
+	}
+	return &DataIter{
+		images:    images,
+		labels:    labels,
+		batchSize: batchSize,
+		transform: transform,
+		order:     order,
+	}
+}
+
+// Len returns the number of samples in the underlying dataset.
+func (d *DataIter) Len() int {
+	return len(d.order)
+}
+
+// Reset rewinds the iterator to the start of the dataset without
+// reordering it.
+func (d *DataIter) Reset() {
+	d.pos = 0
+}
+
+// Shuffle reorders the dataset using seed and rewinds to the start, so the
+// next call to Next begins a freshly shuffled pass.
+func (d *DataIter) Shuffle(seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(d.order), func(i, j int) {
+		d.order[i], d.order[j] = d.order[j], d.order[i]
+	})
+	d.pos = 0
+}
+
+// Next stacks the next batchSize samples (fewer on the final, partial
+// batch) into a single images-by-pixels matrix and a single
+// images-by-labels matrix. ok is false once the dataset has been
+// exhausted; call Reset or Shuffle to start another pass.
+func (d *DataIter) Next() (batchX *mat.Dense, batchY *mat.Dense, ok bool) {
+	if d.pos >= len(d.order) {
+		return nil, nil, false
+	}
+
+	end := d.pos + d.batchSize
+	if end > len(d.order) {
+		end = len(d.order)
+	}
+	idx := d.order[d.pos:end]
+	d.pos = end
+
+	numPixels := len(d.images[idx[0]].Data)
+	numLabels := d.labels[idx[0]].RawMatrix().Cols
+
+	batchX = mat.NewDense(len(idx), numPixels, nil)
+	batchY = mat.NewDense(len(idx), numLabels, nil)
+	for row, sample := range idx {
+		img := d.images[sample]
+		if d.transform != nil {
+			img = d.transform(img)
+		}
+		batchX.SetRow(row, img.Data)
+		batchY.SetRow(row, d.labels[sample].RawRowView(0))
+	}
+
+	return batchX, batchY, true
+}
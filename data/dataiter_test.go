@@ -0,0 +1,117 @@
+package data
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/LdDl/cnns/tensor"
+)
+
+func newTestDataset(n, pixels, classes int) ([]*tensor.Tensor, []*mat.Dense) {
+	images := make([]*tensor.Tensor, n)
+	labels := make([]*mat.Dense, n)
+	for i := 0; i < n; i++ {
+		img := tensor.NewTensor(pixels, 1, 1)
+		for p := range img.Data {
+			img.Data[p] = float64(i)
+		}
+		images[i] = img
+
+		label := mat.NewDense(1, classes, nil)
+		label.Set(0, i%classes, 1)
+		labels[i] = label
+	}
+	return images, labels
+}
+
+// TestNextStacksFullBatches checks that Next stacks exactly batchSize
+// samples per call, in order, until the dataset runs out.
+func TestNextStacksFullBatches(t *testing.T) {
+	images, labels := newTestDataset(6, 4, 3)
+	iter := NewDataIter(images, labels, 2, nil)
+
+	var batches int
+	for {
+		x, y, ok := iter.Next()
+		if !ok {
+			break
+		}
+		batches++
+		r, c := x.Dims()
+		if r != 2 || c != 4 {
+			t.Fatalf("batch %d: batchX dims = (%d, %d), want (2, 4)", batches, r, c)
+		}
+		yr, yc := y.Dims()
+		if yr != 2 || yc != 3 {
+			t.Fatalf("batch %d: batchY dims = (%d, %d), want (2, 3)", batches, yr, yc)
+		}
+	}
+	if batches != 3 {
+		t.Fatalf("got %d batches, want 3", batches)
+	}
+}
+
+// TestNextPartialLastBatch checks that a dataset size not divisible by
+// batchSize yields a short final batch instead of panicking or dropping
+// samples.
+func TestNextPartialLastBatch(t *testing.T) {
+	images, labels := newTestDataset(5, 4, 3)
+	iter := NewDataIter(images, labels, 2, nil)
+
+	var total int
+	for {
+		x, _, ok := iter.Next()
+		if !ok {
+			break
+		}
+		r, _ := x.Dims()
+		total += r
+	}
+	if total != 5 {
+		t.Fatalf("got %d total samples across batches, want 5", total)
+	}
+}
+
+// TestResetRewindsWithoutReordering checks that Reset starts a fresh pass
+// over the same sample order, rather than reshuffling it.
+func TestResetRewindsWithoutReordering(t *testing.T) {
+	images, labels := newTestDataset(4, 4, 2)
+	iter := NewDataIter(images, labels, 4, nil)
+
+	first, _, ok := iter.Next()
+	if !ok {
+		t.Fatal("Next() returned ok=false on first pass")
+	}
+	if _, _, ok := iter.Next(); ok {
+		t.Fatal("Next() returned ok=true after dataset exhausted")
+	}
+
+	iter.Reset()
+	second, _, ok := iter.Next()
+	if !ok {
+		t.Fatal("Next() returned ok=false after Reset")
+	}
+	if !mat.Equal(first, second) {
+		t.Fatalf("Reset() changed batch contents: got %v, want %v", second, first)
+	}
+}
+
+// TestShuffleIsDeterministicPerSeed checks that Shuffle with the same
+// seed produces the same sample order, so augmented training runs stay
+// reproducible.
+func TestShuffleIsDeterministicPerSeed(t *testing.T) {
+	images, labels := newTestDataset(8, 4, 2)
+
+	iterA := NewDataIter(images, labels, 8, nil)
+	iterA.Shuffle(42)
+	batchA, _, _ := iterA.Next()
+
+	iterB := NewDataIter(images, labels, 8, nil)
+	iterB.Shuffle(42)
+	batchB, _, _ := iterB.Next()
+
+	if !mat.Equal(batchA, batchB) {
+		t.Fatalf("Shuffle(42) produced different orders across iterators: %v vs %v", batchA, batchB)
+	}
+}
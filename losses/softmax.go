@@ -0,0 +1,41 @@
+// Package losses implements loss functions used to score model
+// predictions during training and evaluation.
+package losses
+
+import "math"
+
+// Softmax computes a numerically-stable softmax over logits, subtracting
+// the row max before exponentiating so large logits don't overflow.
+func Softmax(logits []float64) []float64 {
+	maxLogit := logits[0]
+	for _, x := range logits[1:] {
+		if x > maxLogit {
+			maxLogit = x
+		}
+	}
+
+	probs := make([]float64, len(logits))
+	sum := 0.0
+	for i, x := range logits {
+		probs[i] = math.Exp(x - maxLogit)
+		sum += probs[i]
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	return probs
+}
+
+// CrossEntropy returns -sum(target_i * log(probs_i)), the cross-entropy
+// between a predicted probability distribution and a one-hot target.
+func CrossEntropy(probs, target []float64) float64 {
+	const epsilon = 1e-12
+	loss := 0.0
+	for i, y := range target {
+		if y == 0 {
+			continue
+		}
+		loss -= y * math.Log(probs[i]+epsilon)
+	}
+	return loss
+}
@@ -1,18 +1,33 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"math"
 	"math/rand"
+	"time"
 
 	"github.com/petar/GoMNIST"
 	"gonum.org/v1/gonum/mat"
 
-	"github.com/LdDl/cnns"
 	"github.com/LdDl/cnns/tensor"
+
+	"github.com/weswest/msds431wk10/architectures"
+	"github.com/weswest/msds431wk10/augment"
+	"github.com/weswest/msds431wk10/checkpoint"
+	"github.com/weswest/msds431wk10/data"
+	"github.com/weswest/msds431wk10/nn"
 )
 
+var validSplit = flag.Float64("valid-split", 0.1, "fraction of the training set to hold out for validation")
+var lossFunction = flag.String("loss", "mse", `loss function to train with: "mse" or "cross-entropy"`)
+var arch = flag.String("arch", "cnn", `model architecture to train: "cnn", "lenet", "resnet", or "vgg"`)
+var learningRate = flag.Float64("learning-rate", 0.01, "SGD learning rate applied after each mini-batch's gradients are averaged")
+var checkpointIn = flag.String("checkpoint-in", "", "path to a checkpoint to load instead of building a fresh model")
+var checkpointOut = flag.String("checkpoint-out", "", "path to save a checkpoint to after training")
+var evalOnly = flag.Bool("eval-only", false, "skip training and only evaluate checkpoint-in against the test set")
+
 // This is related to GoMNIST
 // Print the image to the console
 func printImage(image GoMNIST.RawImage) {
@@ -59,109 +74,271 @@ func convertMNISTForModeling(images []GoMNIST.RawImage) [][]float64 {
 
 	return floatImages
 }
-func createCNN() *cnns.WholeNet {
-	// Create a new neural network
-	net := cnns.WholeNet{
-		LP: cnns.NewLearningParametersDefault(),
+
+// buildModel selects among the architecture zoo, all built on the local nn
+// engine so training below can run real batch-averaged gradients against
+// any of them.
+func buildModel(name string, rng *rand.Rand) *nn.Sequential {
+	switch name {
+	case "lenet":
+		return architectures.BuildLeNet5(28, rng)
+	case "resnet":
+		return architectures.BuildMiniResNet(28, 3, rng)
+	case "vgg":
+		return architectures.BuildVGGMini(28, rng)
+	default:
+		return architectures.BuildCNN(28, rng)
 	}
+}
 
-	// First convolutional layer: 3x3 with 32 filters
-	conv1 := cnns.NewConvLayer(&tensor.TDsize{X: 28, Y: 28, Z: 1}, 32, 3, 1)
-	net.Layers = append(net.Layers, conv1)
+func convertToTensor(images []GoMNIST.RawImage) []*tensor.Tensor {
+	tensors := make([]*tensor.Tensor, len(images))
+	for i, img := range images {
+		t := tensor.NewTensor(28, 28, 1)
+		for j, pixel := range img {
+			t.Data[j] = float64(pixel) / 255.0 // Normalize to [0,1]
+		}
+		tensors[i] = t
+	}
+	return tensors
+}
 
-	// ReLU activation after convolution
-	relu1 := cnns.NewReLULayer(conv1.GetOutputSize())
-	net.Layers = append(net.Layers, relu1)
+// convertToMatrix one-hot encodes GoMNIST labels. The sigmoid MSE head
+// expects the 0.1/0.9 scheme (0/1 targets sit at the asymptotes of the
+// sigmoid, which stalls learning); softmax cross-entropy has no such issue,
+// so it uses true 0/1 targets.
+func convertToMatrix(labels []GoMNIST.Label, useCrossEntropy bool) []*mat.Dense {
+	positive, negative := 0.9, 0.1
+	if useCrossEntropy {
+		positive, negative = 1.0, 0.0
+	}
 
-	// Max pooling layer: 2x2
-	maxpool1 := cnns.NewPoolingLayer(relu1.GetOutputSize(), 2, 2, "max", "valid")
-	net.Layers = append(net.Layers, maxpool1)
+	encoded := make([]*mat.Dense, len(labels))
+	for i, label := range labels {
+		row := make([]float64, 10)
+		for j := range row {
+			row[j] = negative
+		}
+		row[int(label)] = positive
+		encoded[i] = mat.NewDense(1, 10, row)
+	}
+	return encoded
+}
 
-	// Second convolutional layer: 3x3 with 64 filters
-	conv2 := cnns.NewConvLayer(maxpool1.GetOutputSize(), 64, 3, 1)
-	net.Layers = append(net.Layers, conv2)
+// imagesToBatch stacks xs (applying transform to each image, if set) into a
+// single nn.Batch of shape (len(xs), 1, 28, 28).
+func imagesToBatch(xs []*tensor.Tensor, transform augment.Transform) *nn.Batch {
+	b := nn.NewBatch(len(xs), 1, 28, 28)
+	for i, img := range xs {
+		if transform != nil {
+			img = transform(img)
+		}
+		copy(b.Data[i*784:(i+1)*784], img.Data)
+	}
+	return b
+}
 
-	// ReLU activation after convolution
-	relu2 := cnns.NewReLULayer(conv2.GetOutputSize())
-	net.Layers = append(net.Layers, relu2)
+// labelsToBatch stacks one-hot label rows into a single nn.Batch of shape
+// (len(ys), 10, 1, 1).
+func labelsToBatch(ys []*mat.Dense) *nn.Batch {
+	b := nn.NewBatch(len(ys), 10, 1, 1)
+	for i, y := range ys {
+		copy(b.Data[i*10:(i+1)*10], y.RawRowView(0))
+	}
+	return b
+}
 
-	// Max pooling layer: 2x2
-	maxpool2 := cnns.NewPoolingLayer(relu2.GetOutputSize(), 2, 2, "max", "valid")
-	net.Layers = append(net.Layers, maxpool2)
+// denseToBatch reshapes an already-stacked mat.Dense batch (rows=samples,
+// cols=flattened features) into an nn.Batch of the given per-sample shape.
+func denseToBatch(m *mat.Dense, c, h, w int) *nn.Batch {
+	rows, cols := m.Dims()
+	b := nn.NewBatch(rows, c, h, w)
+	for r := 0; r < rows; r++ {
+		copy(b.Data[r*cols:(r+1)*cols], m.RawRowView(r))
+	}
+	return b
+}
 
-	// Fully connected (dense) layer
-	fc1 := cnns.NewFullyConnectedLayer(maxpool2.GetOutputSize(), 128)
-	fc1.SetActivationFunc(cnns.ActivationSygmoid)
-	fc1.SetActivationDerivativeFunc(cnns.ActivationSygmoidDerivative)
-	net.Layers = append(net.Layers, fc1)
+// splitTrainValid carves frac of images/labels off as a validation set,
+// drawn without replacement via rng, and returns the remaining training
+// set plus the validation set. MNIST's on-disk ordering is grouped by
+// writer rather than shuffled, so unlike the gotch loadAll helper's
+// contiguous sli{0, numTrain}/sli{numTrain, numExamples} slicing, this
+// split permutes indices first so both halves see a representative mix
+// of digits and writers.
+func splitTrainValid(images []*tensor.Tensor, labels []*mat.Dense, frac float64, rng *rand.Rand) (trainImages []*tensor.Tensor, trainLabels []*mat.Dense, validImages []*tensor.Tensor, validLabels []*mat.Dense) {
+	order := rng.Perm(len(images))
+	numValid := int(float64(len(images)) * frac)
+	numTrain := len(images) - numValid
+
+	trainImages = make([]*tensor.Tensor, numTrain)
+	trainLabels = make([]*mat.Dense, numTrain)
+	for i, idx := range order[:numTrain] {
+		trainImages[i] = images[idx]
+		trainLabels[i] = labels[idx]
+	}
 
-	// Output layer: Dense with 10 classes (digits)
-	fc2 := cnns.NewFullyConnectedLayer(fc1.GetOutputSize(), 10)
-	fc2.SetActivationFunc(cnns.ActivationSygmoid)
-	fc2.SetActivationDerivativeFunc(cnns.ActivationSygmoidDerivative)
-	net.Layers = append(net.Layers, fc2)
+	validImages = make([]*tensor.Tensor, numValid)
+	validLabels = make([]*mat.Dense, numValid)
+	for i, idx := range order[numTrain:] {
+		validImages[i] = images[idx]
+		validLabels[i] = labels[idx]
+	}
 
-	return &net
+	return trainImages, trainLabels, validImages, validLabels
 }
 
-func convertToTensor(images []GoMNIST.RawImage) []*tensor.Tensor {
-	tensors := make([]*tensor.Tensor, len(images))
-	for i, img := range images {
-		data := make([]float64, len(img))
-		for j, pixel := range img {
-			data[j] = float64(pixel) / 255.0 // Normalize to [0,1]
+// argmaxRow returns the column index of the largest value in sample n of a
+// (N, 10, 1, 1)-shaped batch.
+func argmaxRow(b *nn.Batch, n int) int {
+	best := 0
+	bestVal := b.At(n, 0, 0, 0)
+	for j := 1; j < b.C; j++ {
+		if v := b.At(n, j, 0, 0); v > bestVal {
+			bestVal = v
+			best = j
 		}
-		tensors[i] = tensor.NewTensor(data, 28, 28, 1)
 	}
-	return tensors
+	return best
 }
 
-func tensorToMatrix(t *tensor.Tensor) *mat.Dense {
-	data := t.GetData()
-	return mat.NewDense(t.Dims[0], t.Dims[1]*t.Dims[2], data)
+// evaluate runs a single forward pass over the whole of xs/ys (switching
+// net to eval mode first) and reports the head's average loss,
+// classification accuracy, and a 10x10 confusion matrix indexed
+// [actual][predicted].
+func evaluate(net *nn.Net, xs []*tensor.Tensor, ys []*mat.Dense, transform augment.Transform) (loss, accuracy float64, confusion [10][10]int) {
+	net.SetTraining(false)
+
+	batchX := imagesToBatch(xs, transform)
+	batchY := labelsToBatch(ys)
+	loss, output := net.Evaluate(batchX, batchY)
+
+	correct := 0
+	for n := 0; n < output.N; n++ {
+		predicted := argmaxRow(output, n)
+		actual := argmaxRow(batchY, n)
+		confusion[actual][predicted]++
+		if predicted == actual {
+			correct++
+		}
+	}
+	accuracy = float64(correct) / float64(len(xs))
+	return loss, accuracy, confusion
 }
 
-func trainModel(cnn *cnns.WholeNet, trainImages []*tensor.Tensor, trainLabels []*mat.Dense, epochs int) {
-	for epoch := 0; epoch < epochs; epoch++ {
-		totalLoss := 0.0
-		for i, img := range trainImages {
-			// Convert tensor to matrix
-			imgMatrix := tensorToMatrix(img)
-
-			// Feedforward
-			err := cnn.FeedForward(imgMatrix)
-			if err != nil {
-				log.Printf("Feedforward caused error: %s", err.Error())
-				return
-			}
+// printConfusionSummary prints, for each digit class, its support in the
+// confusion matrix alongside precision and recall derived from it.
+func printConfusionSummary(confusion [10][10]int) {
+	fmt.Println("Label\tSupport\tPrecision\tRecall")
+	fmt.Println("-----\t-------\t---------\t------")
+	for actual := 0; actual < 10; actual++ {
+		support := 0
+		truePositive := confusion[actual][actual]
+		predictedPositive := 0
+		for j := 0; j < 10; j++ {
+			support += confusion[actual][j]
+			predictedPositive += confusion[j][actual]
+		}
 
-			// Get the desired output for the current image
-			desiredOutput := trainLabels[i]
+		precision := 0.0
+		if predictedPositive > 0 {
+			precision = float64(truePositive) / float64(predictedPositive)
+		}
+		recall := 0.0
+		if support > 0 {
+			recall = float64(truePositive) / float64(support)
+		}
+		fmt.Printf("%d\t%d\t%.4f\t\t%.4f\n", actual, support, precision, recall)
+	}
+}
 
-			// Backpropagate
-			err = cnn.Backpropagate(desiredOutput)
-			if err != nil {
-				log.Printf("Backpropagate caused error: %s", err.Error())
-				return
+// printSplitLabelCounts prints, for each digit class, how many examples of
+// it land in the train/valid/test splits, in the same Label/Train/Valid/Test
+// layout as the pre-existing printLabelCounts in mnist.go (which works over
+// the disused gorgonia-backed loader rather than the GoMNIST split used
+// here).
+func printSplitLabelCounts(trainLabels, validLabels, testLabels []*mat.Dense) {
+	countLabels := func(labels []*mat.Dense) [10]int {
+		var counts [10]int
+		for _, row := range labels {
+			values := row.RawRowView(0)
+			best := 0
+			for j := 1; j < len(values); j++ {
+				if values[j] > values[best] {
+					best = j
+				}
 			}
+			counts[best]++
+		}
+		return counts
+	}
+	trainCounts := countLabels(trainLabels)
+	validCounts := countLabels(validLabels)
+	testCounts := countLabels(testLabels)
+
+	fmt.Println("Label\tTrain\tValid\tTest")
+	fmt.Println("-----\t-----\t-----\t-----")
+	for label := 0; label < 10; label++ {
+		fmt.Printf("%d\t%d\t%d\t%d\n", label, trainCounts[label], validCounts[label], testCounts[label])
+	}
+}
+
+// trainModel runs epochs passes over trainImages/trainLabels using
+// batchSize-sized mini-batches drawn from a data.DataIter. Each batch is a
+// single net.TrainStep call: the engine forwards every sample in the batch,
+// accumulates and averages each layer's weight gradient over the whole
+// batch, and applies one update with that averaged gradient, rather than
+// updating once per sample.
+func trainModel(net *nn.Net, trainImages []*tensor.Tensor, trainLabels []*mat.Dense, validImages []*tensor.Tensor, validLabels []*mat.Dense, epochs int, batchSize int, lr float64) {
+	augRng := rand.New(rand.NewSource(431))
+	trainTransform := augment.Compose(
+		augment.RandomShift(2, augRng),
+		augment.RandomRotate(10, augRng),
+		augment.RandomElasticDeform(8, 4, augRng),
+		augment.Normalize(0.5, 0.5),
+	)
+	evalTransform := augment.Normalize(0.5, 0.5)
+
+	iter := data.NewDataIter(trainImages, trainLabels, batchSize, trainTransform)
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		iter.Shuffle(int64(epoch))
+		net.SetTraining(true)
 
-			// Accumulate loss (for demonstration purposes, using MSE here)
-			prediction := cnn.Layers[len(cnn.Layers)-1].(*cnns.FullyConnectedLayer).GetOutput() // Assuming the last layer is a FullyConnectedLayer
-			loss := 0.0
-			for j := 0; j < 10; j++ {
-				diff := prediction.At(0, j) - desiredOutput.At(0, j)
-				loss += diff * diff
+		start := time.Now()
+		totalLoss := 0.0
+		numSamples := 0
+
+		for {
+			batchX, batchY, ok := iter.Next()
+			if !ok {
+				break
 			}
-			totalLoss += loss
+
+			rows, _ := batchX.Dims()
+			x := denseToBatch(batchX, 1, 28, 28)
+			y := denseToBatch(batchY, 10, 1, 1)
+
+			batchLoss := net.TrainStep(x, y, lr)
+			totalLoss += batchLoss * float64(rows)
+			numSamples += rows
 		}
-		avgLoss := totalLoss / float64(len(trainImages))
-		fmt.Printf("Epoch %d: Average Loss: %f\n", epoch+1, avgLoss)
+
+		elapsed := time.Since(start)
+		avgLoss := totalLoss / float64(numSamples)
+		samplesPerSec := float64(numSamples) / elapsed.Seconds()
+		fmt.Printf("Epoch %d: Average Loss: %f, Time: %s, Samples/sec: %.1f\n", epoch+1, avgLoss, elapsed, samplesPerSec)
+
+		validLoss, validAccuracy, _ := evaluate(net, validImages, validLabels, evalTransform)
+		fmt.Printf("Epoch %d: Validation Loss: %f, Validation Accuracy: %.4f\n", epoch+1, validLoss, validAccuracy)
 	}
 }
 
 // ... [Rest of your code]
 
 func main() {
+	flag.Parse()
+
 	rng := rand.New(rand.NewSource(431)) //Obvi.
 	fmt.Println("Random number: ", rng.Intn(100))
 
@@ -184,13 +361,55 @@ func main() {
 	inputData := convertMNISTForModeling(train.Images)
 	fmt.Println(inputData)
 
+	useCrossEntropy := *lossFunction == "cross-entropy"
+
 	// Convert MNIST data to suitable format
 	trainTensors := convertToTensor(train.Images)
-	trainMatrix := convertToMatrix(train.Labels)
+	trainMatrix := convertToMatrix(train.Labels, useCrossEntropy)
+	testTensors := convertToTensor(test.Images)
+	testMatrix := convertToMatrix(test.Labels, useCrossEntropy)
+
+	// Hold out a validation split from the training set
+	trainTensors, trainMatrix, validTensors, validMatrix := splitTrainValid(trainTensors, trainMatrix, *validSplit, rng)
+	printSplitLabelCounts(trainMatrix, validMatrix, testMatrix)
+
+	if *evalOnly && *checkpointIn == "" {
+		log.Fatalf("--eval-only requires --checkpoint-in (there is no trained model to evaluate otherwise)")
+	}
+
+	// Create the model, or load one from a checkpoint
+	var body *nn.Sequential
+	if *checkpointIn != "" {
+		body, err = checkpoint.LoadCheckpoint(*checkpointIn)
+		if err != nil {
+			log.Fatalf("Failed to load checkpoint %s: %s", *checkpointIn, err.Error())
+		}
+	} else {
+		body = buildModel(*arch, rng)
+	}
+
+	// SoftmaxCrossEntropy computes the real softmax(logits)-target gradient
+	// and feeds it straight into body.Backward, rather than approximating it
+	// through an identity-activation trick on a sigmoid-shaped layer.
+	var head nn.OutputHead = nn.NewSigmoidMSE()
+	if useCrossEntropy {
+		head = nn.NewSoftmaxCrossEntropy()
+	}
+	net := nn.NewNet(body, head)
 
-	// Create the CNN model
-	cnn := createCNN()
+	if !*evalOnly {
+		// Train the model, evaluating against the validation split every epoch
+		trainModel(net, trainTensors, trainMatrix, validTensors, validMatrix, 10, 256, *learningRate) // Training for 10 epochs, mini-batches of 256
+	}
+
+	// Final evaluation against the held-out test set
+	testLoss, testAccuracy, testConfusion := evaluate(net, testTensors, testMatrix, augment.Normalize(0.5, 0.5))
+	fmt.Printf("Test Loss: %f, Test Accuracy: %.4f\n", testLoss, testAccuracy)
+	printConfusionSummary(testConfusion)
 
-	// Train the model
-	trainModel(cnn, trainTensors, trainMatrix, 10) // Training for 10 epochs as an example
+	if *checkpointOut != "" {
+		if err := checkpoint.SaveCheckpoint(body, *checkpointOut); err != nil {
+			log.Fatalf("Failed to save checkpoint %s: %s", *checkpointOut, err.Error())
+		}
+	}
 }
@@ -0,0 +1,195 @@
+// Running these with plain `go test` can panic during init on Go 1.21+
+// toolchains: mnist.go's gorgonia.org/tensor import pulls in
+// go4.org/unsafe/assume-no-moving-gc, which refuses to load unless
+// ASSUME_NO_MOVING_GC_UNSAFE_RISK_IT_WITH=go1.21 is set. That's a
+// toolchain/dependency mismatch predating this test file, not something
+// these tests trigger.
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/petar/GoMNIST"
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/LdDl/cnns/tensor"
+
+	"github.com/weswest/msds431wk10/nn"
+)
+
+// pickBody builds a Sequential whose single Dense layer copies its first
+// ten input pixels straight through to the ten output logits (weight is
+// the identity on those features, zero elsewhere, bias zero), so a test
+// can choose a sample's predicted class just by setting pixel p to 1 and
+// every other pixel to 0.
+func pickBody() *nn.Sequential {
+	dense := nn.NewDense(784, 10, rand.New(rand.NewSource(0)))
+	for i := range dense.Weight.Data {
+		dense.Weight.Data[i] = 0
+	}
+	for o := 0; o < 10; o++ {
+		dense.Weight.Data[o*784+o] = 1
+	}
+	return nn.NewSequential(dense)
+}
+
+func pickImage(predicted int) *tensor.Tensor {
+	t := tensor.NewTensor(28, 28, 1)
+	t.Data[predicted] = 1
+	return t
+}
+
+func oneHot(actual int) *mat.Dense {
+	row := make([]float64, 10)
+	row[actual] = 1
+	return mat.NewDense(1, 10, row)
+}
+
+// TestEvaluateConfusionMatrix checks evaluate's confusion matrix against a
+// hand-built one, including cases where predicted != actual, to catch a
+// confusion[actual][predicted] vs confusion[predicted][actual]
+// transposition.
+func TestEvaluateConfusionMatrix(t *testing.T) {
+	cases := []struct{ actual, predicted int }{
+		{actual: 0, predicted: 0},
+		{actual: 1, predicted: 1},
+		{actual: 2, predicted: 5},
+		{actual: 5, predicted: 5},
+		{actual: 7, predicted: 2},
+	}
+
+	var xs []*tensor.Tensor
+	var ys []*mat.Dense
+	want := [10][10]int{}
+	wantCorrect := 0
+	for _, c := range cases {
+		xs = append(xs, pickImage(c.predicted))
+		ys = append(ys, oneHot(c.actual))
+		want[c.actual][c.predicted]++
+		if c.actual == c.predicted {
+			wantCorrect++
+		}
+	}
+
+	net := nn.NewNet(pickBody(), nn.NewSoftmaxCrossEntropy())
+	_, accuracy, confusion := evaluate(net, xs, ys, nil)
+
+	if confusion != want {
+		t.Fatalf("confusion = %v, want %v", confusion, want)
+	}
+	wantAccuracy := float64(wantCorrect) / float64(len(cases))
+	if accuracy != wantAccuracy {
+		t.Fatalf("accuracy = %v, want %v", accuracy, wantAccuracy)
+	}
+}
+
+// TestPrintConfusionSummary checks precision/recall against a hand-built
+// confusion matrix: label 0 has 2 true positives, 1 false negative (support
+// 3) and 1 false positive (another label predicted as 0), so precision =
+// 2/3 and recall = 2/3.
+func TestPrintConfusionSummary(t *testing.T) {
+	var confusion [10][10]int
+	confusion[0][0] = 2
+	confusion[0][1] = 1 // a 0 misclassified as 1 (false negative for 0)
+	confusion[2][0] = 1 // a 2 misclassified as 0 (false positive for 0)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = w
+	printConfusionSummary(confusion)
+	w.Close()
+	os.Stdout = origStdout
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("io.Copy: %v", err)
+	}
+
+	const wantLine = "0\t3\t0.6667\t\t0.6667"
+	if !bytes.Contains(buf.Bytes(), []byte(wantLine)) {
+		t.Fatalf("output = %q, want a line containing %q", buf.String(), wantLine)
+	}
+}
+
+// TestSplitTrainValidSizesAndNoOverlap checks that splitTrainValid carves
+// off exactly frac of the dataset (rounded down) and that the two halves
+// partition the original set with no overlap and no dropped samples.
+func TestSplitTrainValidSizesAndNoOverlap(t *testing.T) {
+	const n = 50
+	images := make([]*tensor.Tensor, n)
+	labels := make([]*mat.Dense, n)
+	for i := 0; i < n; i++ {
+		images[i] = pickImage(i % 10)
+		labels[i] = oneHot(i % 10)
+	}
+
+	trainImages, trainLabels, validImages, validLabels := splitTrainValid(images, labels, 0.2, rand.New(rand.NewSource(1)))
+
+	wantValid := 10
+	wantTrain := n - wantValid
+	if len(validImages) != wantValid || len(validLabels) != wantValid {
+		t.Fatalf("valid split size = %d, want %d", len(validImages), wantValid)
+	}
+	if len(trainImages) != wantTrain || len(trainLabels) != wantTrain {
+		t.Fatalf("train split size = %d, want %d", len(trainImages), wantTrain)
+	}
+
+	seen := make(map[*tensor.Tensor]bool, n)
+	for _, img := range trainImages {
+		if seen[img] {
+			t.Fatalf("image %p appears more than once across the split", img)
+		}
+		seen[img] = true
+	}
+	for _, img := range validImages {
+		if seen[img] {
+			t.Fatalf("image %p appears in both train and valid", img)
+		}
+		seen[img] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("split covers %d distinct images, want %d", len(seen), n)
+	}
+}
+
+// TestConvertToMatrixOneHotScheme checks the useCrossEntropy switch in
+// convertToMatrix: the sigmoid MSE head must get the 0.1/0.9 scheme (true
+// 0/1 targets sit at the sigmoid's asymptotes and stall learning), while
+// softmax cross-entropy must get true 0/1 targets.
+func TestConvertToMatrixOneHotScheme(t *testing.T) {
+	cases := []struct {
+		name            string
+		useCrossEntropy bool
+		wantPositive    float64
+		wantNegative    float64
+	}{
+		{name: "mse", useCrossEntropy: false, wantPositive: 0.9, wantNegative: 0.1},
+		{name: "cross-entropy", useCrossEntropy: true, wantPositive: 1.0, wantNegative: 0.0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoded := convertToMatrix([]GoMNIST.Label{3}, c.useCrossEntropy)
+			if len(encoded) != 1 {
+				t.Fatalf("len(encoded) = %d, want 1", len(encoded))
+			}
+			row := encoded[0].RawRowView(0)
+			for j, v := range row {
+				want := c.wantNegative
+				if j == 3 {
+					want = c.wantPositive
+				}
+				if v != want {
+					t.Fatalf("encoded[0][%d] = %v, want %v", j, v, want)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,14 @@ import (
 const numLabels = 10
 const pixelRange = 255
 
+// sli is a single-step tensor.Slice spanning [start, end), for slicing off
+// the train/valid split in loadAll.
+type sli struct{ start, end int }
+
+func (s sli) Start() int { return s.start }
+func (s sli) End() int   { return s.end }
+func (s sli) Step() int  { return 1 }
+
 const (
 	imageMagic = 0x00000803
 	labelMagic = 0x00000801
@@ -0,0 +1,67 @@
+// Package nn is a small, self-contained CNN engine: conv/pool/dense layers
+// with real forward and backward passes over Go's own slices, so training
+// can do genuine mini-batch gradient averaging, expose real weights for
+// checkpointing, and support layers (BatchNorm, Dropout, residual blocks)
+// that need more than an elementwise activation hook. cnns.WholeNet only
+// exposes per-sample FeedForward/Backpropagate with no way to intercept a
+// gradient before the weight update, which is what these layers need.
+package nn
+
+// Batch holds a batch of N samples, each shaped C x H x W, as a flat slice
+// in row-major (n, c, h, w) order. Dense output uses H=W=1 with C holding
+// the feature count.
+type Batch struct {
+	N, C, H, W int
+	Data       []float64
+}
+
+// NewBatch allocates a zeroed Batch of the given shape.
+func NewBatch(n, c, h, w int) *Batch {
+	return &Batch{N: n, C: c, H: h, W: w, Data: make([]float64, n*c*h*w)}
+}
+
+// At returns the value at (n, c, h, w).
+func (b *Batch) At(n, c, h, w int) float64 {
+	return b.Data[((n*b.C+c)*b.H+h)*b.W+w]
+}
+
+// Set stores v at (n, c, h, w).
+func (b *Batch) Set(n, c, h, w int, v float64) {
+	b.Data[((n*b.C+c)*b.H+h)*b.W+w] = v
+}
+
+// Features returns the per-sample element count C*H*W.
+func (b *Batch) Features() int {
+	return b.C * b.H * b.W
+}
+
+// Param is a learnable parameter vector (weights, biases, or a BatchNorm
+// scale/shift) alongside the gradient accumulated for it during the most
+// recent Backward call.
+type Param struct {
+	Data []float64
+	Grad []float64
+}
+
+// NewParam allocates a zeroed Param of length n.
+func NewParam(n int) *Param {
+	return &Param{Data: make([]float64, n), Grad: make([]float64, n)}
+}
+
+// Update applies one SGD step: Data -= lr * Grad.
+func (p *Param) Update(lr float64) {
+	for i := range p.Data {
+		p.Data[i] -= lr * p.Grad[i]
+	}
+}
+
+// Layer is one step of a network: a forward pass over a batch, and a
+// backward pass that consumes the gradient w.r.t. its output and returns
+// the gradient w.r.t. its input, accumulating gradients for any of its own
+// Params along the way (already averaged over the batch).
+type Layer interface {
+	Forward(x *Batch) *Batch
+	Backward(gradOut *Batch) *Batch
+	SetTraining(training bool)
+	Params() []*Param
+}
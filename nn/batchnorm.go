@@ -0,0 +1,134 @@
+package nn
+
+import "math"
+
+// BatchNorm normalizes each channel across a batch using the batch's own
+// mean/variance in training mode (while also updating a running estimate),
+// and the running estimate alone in eval mode. H and W are 1 for a
+// dense-head BatchNorm (one "channel" per feature) or the spatial
+// dimensions for a conv-head BatchNorm (mean/variance taken over N*H*W).
+type BatchNorm struct {
+	C                       int
+	Momentum, Eps           float64
+	Gamma, Beta             *Param // len C
+	RunningMean, RunningVar []float64
+
+	training bool
+
+	input        *Batch
+	normalized   []float64
+	mean, stddev []float64
+}
+
+// NewBatchNorm builds a BatchNorm over c channels, with Gamma initialized
+// to 1 and Beta to 0.
+func NewBatchNorm(c int, momentum, eps float64) *BatchNorm {
+	gamma := NewParam(c)
+	for i := range gamma.Data {
+		gamma.Data[i] = 1
+	}
+	return &BatchNorm{
+		C: c, Momentum: momentum, Eps: eps,
+		Gamma: gamma, Beta: NewParam(c),
+		RunningMean: make([]float64, c), RunningVar: make([]float64, c),
+	}
+}
+
+func (b *BatchNorm) SetTraining(training bool) { b.training = training }
+
+func (b *BatchNorm) Forward(x *Batch) *Batch {
+	b.input = x
+	hw := x.H * x.W
+	count := float64(x.N * hw)
+	out := NewBatch(x.N, x.C, x.H, x.W)
+	b.normalized = make([]float64, len(x.Data))
+	b.mean = make([]float64, x.C)
+	b.stddev = make([]float64, x.C)
+
+	for c := 0; c < x.C; c++ {
+		var mean, variance float64
+		if b.training {
+			for n := 0; n < x.N; n++ {
+				for p := 0; p < hw; p++ {
+					mean += x.Data[(n*x.C+c)*hw+p]
+				}
+			}
+			mean /= count
+			for n := 0; n < x.N; n++ {
+				for p := 0; p < hw; p++ {
+					diff := x.Data[(n*x.C+c)*hw+p] - mean
+					variance += diff * diff
+				}
+			}
+			variance /= count
+			b.RunningMean[c] = b.Momentum*b.RunningMean[c] + (1-b.Momentum)*mean
+			b.RunningVar[c] = b.Momentum*b.RunningVar[c] + (1-b.Momentum)*variance
+		} else {
+			mean = b.RunningMean[c]
+			variance = b.RunningVar[c]
+		}
+
+		stddev := math.Sqrt(variance + b.Eps)
+		b.mean[c] = mean
+		b.stddev[c] = stddev
+		for n := 0; n < x.N; n++ {
+			for p := 0; p < hw; p++ {
+				idx := (n*x.C+c)*hw + p
+				normalized := (x.Data[idx] - mean) / stddev
+				b.normalized[idx] = normalized
+				out.Data[idx] = normalized*b.Gamma.Data[c] + b.Beta.Data[c]
+			}
+		}
+	}
+	return out
+}
+
+// Backward implements the standard batchnorm gradient: with xhat the
+// normalized input and dxhat = gradOut*gamma,
+//
+//	gradIn = (1/(M*stddev)) * (M*dxhat - sum(dxhat) - xhat*sum(dxhat*xhat))
+//
+// over the M = N*H*W elements sharing a channel's statistics.
+func (b *BatchNorm) Backward(gradOut *Batch) *Batch {
+	x := b.input
+	hw := x.H * x.W
+	count := float64(x.N * hw)
+	gradIn := NewBatch(x.N, x.C, x.H, x.W)
+	b.Gamma.Grad = make([]float64, len(b.Gamma.Grad))
+	b.Beta.Grad = make([]float64, len(b.Beta.Grad))
+
+	for c := 0; c < x.C; c++ {
+		gamma := b.Gamma.Data[c]
+		stddev := b.stddev[c]
+
+		var sumDxhat, sumDxhatXhat, sumGrad, sumGradXhat float64
+		for n := 0; n < x.N; n++ {
+			for p := 0; p < hw; p++ {
+				idx := (n*x.C+c)*hw + p
+				g := gradOut.Data[idx]
+				xhat := b.normalized[idx]
+				sumGrad += g
+				sumGradXhat += g * xhat
+				dxhat := g * gamma
+				sumDxhat += dxhat
+				sumDxhatXhat += dxhat * xhat
+			}
+		}
+		// Matches Conv2D/Dense's convention: sum over the spatial positions
+		// a channel's gamma/beta are shared across, average over the batch.
+		b.Beta.Grad[c] = sumGrad / float64(x.N)
+		b.Gamma.Grad[c] = sumGradXhat / float64(x.N)
+
+		for n := 0; n < x.N; n++ {
+			for p := 0; p < hw; p++ {
+				idx := (n*x.C+c)*hw + p
+				dxhat := gradOut.Data[idx] * gamma
+				xhat := b.normalized[idx]
+				gradIn.Data[idx] = (count*dxhat - sumDxhat - xhat*sumDxhatXhat) / (count * stddev)
+			}
+		}
+	}
+	return gradIn
+}
+
+func (b *BatchNorm) Params() []*Param { return []*Param{b.Gamma, b.Beta} }
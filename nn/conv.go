@@ -0,0 +1,116 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Conv2D is a square-kernel, stride/pad-configurable 2D convolution over a
+// batch. Gradients accumulated in Backward are averaged over the batch, so
+// a single Update(lr) call applies a true mini-batch gradient step.
+type Conv2D struct {
+	InC, OutC, K, Stride, Pad int
+	Weight                    *Param // OutC*InC*K*K
+	Bias                      *Param // OutC
+
+	input      *Batch
+	outH, outW int
+}
+
+// NewConv2D builds a Conv2D with small random weights drawn from rng, scaled
+// for the layer's fan-in.
+func NewConv2D(inC, outC, k, stride, pad int, rng *rand.Rand) *Conv2D {
+	w := NewParam(outC * inC * k * k)
+	scale := math.Sqrt(2.0 / float64(inC*k*k))
+	for i := range w.Data {
+		w.Data[i] = (rng.Float64()*2 - 1) * scale
+	}
+	return &Conv2D{InC: inC, OutC: outC, K: k, Stride: stride, Pad: pad, Weight: w, Bias: NewParam(outC)}
+}
+
+func convOutSize(in, k, stride, pad int) int {
+	return (in+2*pad-k)/stride + 1
+}
+
+func (c *Conv2D) Forward(x *Batch) *Batch {
+	c.input = x
+	c.outH = convOutSize(x.H, c.K, c.Stride, c.Pad)
+	c.outW = convOutSize(x.W, c.K, c.Stride, c.Pad)
+	out := NewBatch(x.N, c.OutC, c.outH, c.outW)
+
+	for n := 0; n < x.N; n++ {
+		for oc := 0; oc < c.OutC; oc++ {
+			for oh := 0; oh < c.outH; oh++ {
+				for ow := 0; ow < c.outW; ow++ {
+					sum := c.Bias.Data[oc]
+					for ic := 0; ic < c.InC; ic++ {
+						for kh := 0; kh < c.K; kh++ {
+							ih := oh*c.Stride + kh - c.Pad
+							if ih < 0 || ih >= x.H {
+								continue
+							}
+							for kw := 0; kw < c.K; kw++ {
+								iw := ow*c.Stride + kw - c.Pad
+								if iw < 0 || iw >= x.W {
+									continue
+								}
+								wIdx := ((oc*c.InC+ic)*c.K+kh)*c.K + kw
+								sum += x.At(n, ic, ih, iw) * c.Weight.Data[wIdx]
+							}
+						}
+					}
+					out.Set(n, oc, oh, ow, sum)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (c *Conv2D) Backward(gradOut *Batch) *Batch {
+	x := c.input
+	gradIn := NewBatch(x.N, x.C, x.H, x.W)
+	c.Weight.Grad = make([]float64, len(c.Weight.Grad))
+	c.Bias.Grad = make([]float64, len(c.Bias.Grad))
+
+	for n := 0; n < x.N; n++ {
+		for oc := 0; oc < c.OutC; oc++ {
+			for oh := 0; oh < c.outH; oh++ {
+				for ow := 0; ow < c.outW; ow++ {
+					g := gradOut.At(n, oc, oh, ow)
+					c.Bias.Grad[oc] += g
+					for ic := 0; ic < c.InC; ic++ {
+						for kh := 0; kh < c.K; kh++ {
+							ih := oh*c.Stride + kh - c.Pad
+							if ih < 0 || ih >= x.H {
+								continue
+							}
+							for kw := 0; kw < c.K; kw++ {
+								iw := ow*c.Stride + kw - c.Pad
+								if iw < 0 || iw >= x.W {
+									continue
+								}
+								wIdx := ((oc*c.InC+ic)*c.K+kh)*c.K + kw
+								gradIn.Data[((n*x.C+ic)*x.H+ih)*x.W+iw] += g * c.Weight.Data[wIdx]
+								c.Weight.Grad[wIdx] += g * x.At(n, ic, ih, iw)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	scale := 1.0 / float64(x.N)
+	for i := range c.Weight.Grad {
+		c.Weight.Grad[i] *= scale
+	}
+	for i := range c.Bias.Grad {
+		c.Bias.Grad[i] *= scale
+	}
+	return gradIn
+}
+
+func (c *Conv2D) SetTraining(bool) {}
+
+func (c *Conv2D) Params() []*Param { return []*Param{c.Weight, c.Bias} }
@@ -0,0 +1,76 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Dense is a fully-connected layer: it flattens its input's C*H*W into a
+// feature vector per sample and applies y = xW^T + b. Gradients accumulated
+// in Backward are averaged over the batch.
+type Dense struct {
+	InFeatures, OutFeatures int
+	Weight                  *Param // OutFeatures*InFeatures
+	Bias                    *Param // OutFeatures
+
+	input *Batch
+}
+
+// NewDense builds a Dense layer with small random weights drawn from rng,
+// scaled for the layer's fan-in.
+func NewDense(inFeatures, outFeatures int, rng *rand.Rand) *Dense {
+	w := NewParam(outFeatures * inFeatures)
+	scale := math.Sqrt(2.0 / float64(inFeatures))
+	for i := range w.Data {
+		w.Data[i] = (rng.Float64()*2 - 1) * scale
+	}
+	return &Dense{InFeatures: inFeatures, OutFeatures: outFeatures, Weight: w, Bias: NewParam(outFeatures)}
+}
+
+func (d *Dense) Forward(x *Batch) *Batch {
+	d.input = x
+	features := x.Features()
+	out := NewBatch(x.N, d.OutFeatures, 1, 1)
+	for n := 0; n < x.N; n++ {
+		for o := 0; o < d.OutFeatures; o++ {
+			sum := d.Bias.Data[o]
+			for i := 0; i < features; i++ {
+				sum += x.Data[n*features+i] * d.Weight.Data[o*d.InFeatures+i]
+			}
+			out.Set(n, o, 0, 0, sum)
+		}
+	}
+	return out
+}
+
+func (d *Dense) Backward(gradOut *Batch) *Batch {
+	x := d.input
+	features := x.Features()
+	gradIn := NewBatch(x.N, x.C, x.H, x.W)
+	d.Weight.Grad = make([]float64, len(d.Weight.Grad))
+	d.Bias.Grad = make([]float64, len(d.Bias.Grad))
+
+	for n := 0; n < x.N; n++ {
+		for o := 0; o < d.OutFeatures; o++ {
+			g := gradOut.At(n, o, 0, 0)
+			d.Bias.Grad[o] += g
+			for i := 0; i < features; i++ {
+				gradIn.Data[n*features+i] += g * d.Weight.Data[o*d.InFeatures+i]
+				d.Weight.Grad[o*d.InFeatures+i] += g * x.Data[n*features+i]
+			}
+		}
+	}
+
+	scale := 1.0 / float64(x.N)
+	for i := range d.Weight.Grad {
+		d.Weight.Grad[i] *= scale
+	}
+	for i := range d.Bias.Grad {
+		d.Bias.Grad[i] *= scale
+	}
+	return gradIn
+}
+
+func (d *Dense) SetTraining(bool) {}
+
+func (d *Dense) Params() []*Param { return []*Param{d.Weight, d.Bias} }
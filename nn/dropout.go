@@ -0,0 +1,56 @@
+package nn
+
+import "math/rand"
+
+// Dropout zeroes each input with probability P during training and scales
+// the survivors by 1/(1-P) so the expected activation is unchanged; in
+// eval mode it is the identity.
+type Dropout struct {
+	P   float64
+	Rng *rand.Rand
+
+	training bool
+	mask     []float64
+}
+
+// NewDropout creates a Dropout layer that drops each unit with probability
+// p, drawing its mask from rng.
+func NewDropout(p float64, rng *rand.Rand) *Dropout {
+	return &Dropout{P: p, Rng: rng}
+}
+
+func (d *Dropout) SetTraining(training bool) { d.training = training }
+
+func (d *Dropout) Forward(x *Batch) *Batch {
+	out := NewBatch(x.N, x.C, x.H, x.W)
+	if !d.training {
+		copy(out.Data, x.Data)
+		d.mask = nil
+		return out
+	}
+
+	scale := 1.0 / (1.0 - d.P)
+	mask := make([]float64, len(x.Data))
+	for i, v := range x.Data {
+		if d.Rng.Float64() >= d.P {
+			mask[i] = scale
+			out.Data[i] = v * scale
+		}
+	}
+	d.mask = mask
+	return out
+}
+
+func (d *Dropout) Backward(gradOut *Batch) *Batch {
+	gradIn := NewBatch(gradOut.N, gradOut.C, gradOut.H, gradOut.W)
+	if d.mask == nil {
+		copy(gradIn.Data, gradOut.Data)
+		return gradIn
+	}
+	for i := range gradOut.Data {
+		gradIn.Data[i] = gradOut.Data[i] * d.mask[i]
+	}
+	return gradIn
+}
+
+func (d *Dropout) Params() []*Param { return nil }
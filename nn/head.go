@@ -0,0 +1,90 @@
+package nn
+
+import (
+	"math"
+
+	"github.com/weswest/msds431wk10/losses"
+)
+
+// OutputHead turns a network's raw logits into a prediction, a scalar loss
+// against one-hot targets, and the per-sample gradient w.r.t. those logits
+// (not divided by the batch size, matching every other layer's Backward,
+// which passes gradients back per sample and averages only its own Params'
+// gradients over the batch), so Net.TrainStep can always finish with one
+// Body.Backward(grad) call regardless of which head is in use.
+type OutputHead interface {
+	Forward(logits *Batch) *Batch
+	Loss(output, target *Batch) float64
+	Backward(output, target *Batch) *Batch
+}
+
+// SoftmaxCrossEntropy is the standard classification head: softmax turns
+// logits into a probability row per sample, cross-entropy scores it against
+// a one-hot target, and their combined gradient w.r.t. the logits is the
+// exact probs-target difference (no separate softmax Jacobian needed, since
+// the log and exp cancel).
+type SoftmaxCrossEntropy struct{}
+
+func NewSoftmaxCrossEntropy() *SoftmaxCrossEntropy { return &SoftmaxCrossEntropy{} }
+
+func (h *SoftmaxCrossEntropy) Forward(logits *Batch) *Batch {
+	features := logits.Features()
+	out := NewBatch(logits.N, logits.C, logits.H, logits.W)
+	for n := 0; n < logits.N; n++ {
+		row := logits.Data[n*features : (n+1)*features]
+		probs := losses.Softmax(row)
+		copy(out.Data[n*features:(n+1)*features], probs)
+	}
+	return out
+}
+
+func (h *SoftmaxCrossEntropy) Loss(output, target *Batch) float64 {
+	features := output.Features()
+	total := 0.0
+	for n := 0; n < output.N; n++ {
+		probs := output.Data[n*features : (n+1)*features]
+		labels := target.Data[n*features : (n+1)*features]
+		total += losses.CrossEntropy(probs, labels)
+	}
+	return total / float64(output.N)
+}
+
+func (h *SoftmaxCrossEntropy) Backward(output, target *Batch) *Batch {
+	gradIn := NewBatch(output.N, output.C, output.H, output.W)
+	for i := range output.Data {
+		gradIn.Data[i] = output.Data[i] - target.Data[i]
+	}
+	return gradIn
+}
+
+// SigmoidMSE is a sigmoid output activation scored by squared error,
+// matching the original (pre-cross-entropy) training setup.
+type SigmoidMSE struct{}
+
+func NewSigmoidMSE() *SigmoidMSE { return &SigmoidMSE{} }
+
+func (h *SigmoidMSE) Forward(logits *Batch) *Batch {
+	out := NewBatch(logits.N, logits.C, logits.H, logits.W)
+	for i, v := range logits.Data {
+		out.Data[i] = 1 / (1 + math.Exp(-v))
+	}
+	return out
+}
+
+func (h *SigmoidMSE) Loss(output, target *Batch) float64 {
+	total := 0.0
+	for i := range output.Data {
+		diff := output.Data[i] - target.Data[i]
+		total += diff * diff
+	}
+	return total / float64(output.N)
+}
+
+func (h *SigmoidMSE) Backward(output, target *Batch) *Batch {
+	gradIn := NewBatch(output.N, output.C, output.H, output.W)
+	for i := range output.Data {
+		o := output.Data[i]
+		gradIn.Data[i] = 2 * (o - target.Data[i]) * o * (1 - o)
+	}
+	return gradIn
+}
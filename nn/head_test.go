@@ -0,0 +1,38 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/weswest/msds431wk10/losses"
+)
+
+// TestSoftmaxCrossEntropyGradient checks that Backward returns exactly
+// softmax(logits)-target per sample (the layers do the batch averaging of
+// their own parameter gradients), the gradient that
+// trainModel now feeds into the FC weight update in place of the old
+// cnns-identity-activation approximation.
+func TestSoftmaxCrossEntropyGradient(t *testing.T) {
+	head := NewSoftmaxCrossEntropy()
+
+	logits := NewBatch(1, 3, 1, 1)
+	logits.Data = []float64{2.0, 1.0, 0.1}
+	target := NewBatch(1, 3, 1, 1)
+	target.Data = []float64{1, 0, 0}
+
+	output := head.Forward(logits)
+	wantProbs := losses.Softmax(logits.Data)
+	for i, p := range wantProbs {
+		if math.Abs(output.Data[i]-p) > 1e-12 {
+			t.Fatalf("Forward()[%d] = %v, want %v", i, output.Data[i], p)
+		}
+	}
+
+	grad := head.Backward(output, target)
+	for i := range grad.Data {
+		want := output.Data[i] - target.Data[i] // no batch-size scaling in the head
+		if math.Abs(grad.Data[i]-want) > 1e-12 {
+			t.Fatalf("Backward()[%d] = %v, want %v", i, grad.Data[i], want)
+		}
+	}
+}
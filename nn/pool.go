@@ -0,0 +1,73 @@
+package nn
+
+import "math"
+
+// MaxPool2D is a square max pool with a configurable stride over a batch's
+// spatial dimensions.
+type MaxPool2D struct {
+	K, Stride int
+
+	inN, inC, inH, inW int
+	outH, outW         int
+	argmax             []int // flat index into the input per output element
+}
+
+func NewMaxPool2D(k, stride int) *MaxPool2D { return &MaxPool2D{K: k, Stride: stride} }
+
+func poolOutSize(in, k, stride int) int {
+	return (in-k)/stride + 1
+}
+
+func (p *MaxPool2D) Forward(x *Batch) *Batch {
+	p.inN, p.inC, p.inH, p.inW = x.N, x.C, x.H, x.W
+	p.outH = poolOutSize(x.H, p.K, p.Stride)
+	p.outW = poolOutSize(x.W, p.K, p.Stride)
+	out := NewBatch(x.N, x.C, p.outH, p.outW)
+	p.argmax = make([]int, x.N*x.C*p.outH*p.outW)
+
+	idx := 0
+	for n := 0; n < x.N; n++ {
+		for c := 0; c < x.C; c++ {
+			for oh := 0; oh < p.outH; oh++ {
+				for ow := 0; ow < p.outW; ow++ {
+					best := math.Inf(-1)
+					bestIdx := -1
+					for kh := 0; kh < p.K; kh++ {
+						ih := oh*p.Stride + kh
+						for kw := 0; kw < p.K; kw++ {
+							iw := ow*p.Stride + kw
+							v := x.At(n, c, ih, iw)
+							if v > best {
+								best = v
+								bestIdx = ((n*x.C+c)*x.H+ih)*x.W + iw
+							}
+						}
+					}
+					out.Set(n, c, oh, ow, best)
+					p.argmax[idx] = bestIdx
+					idx++
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (p *MaxPool2D) Backward(gradOut *Batch) *Batch {
+	gradIn := NewBatch(p.inN, p.inC, p.inH, p.inW)
+	idx := 0
+	for n := 0; n < p.inN; n++ {
+		for c := 0; c < p.inC; c++ {
+			for oh := 0; oh < p.outH; oh++ {
+				for ow := 0; ow < p.outW; ow++ {
+					gradIn.Data[p.argmax[idx]] += gradOut.At(n, c, oh, ow)
+					idx++
+				}
+			}
+		}
+	}
+	return gradIn
+}
+
+func (p *MaxPool2D) SetTraining(bool) {}
+func (p *MaxPool2D) Params() []*Param { return nil }
@@ -0,0 +1,62 @@
+package nn
+
+import "math"
+
+// ReLU applies max(0, x) elementwise.
+type ReLU struct {
+	mask []float64
+}
+
+func NewReLU() *ReLU { return &ReLU{} }
+
+func (r *ReLU) Forward(x *Batch) *Batch {
+	out := NewBatch(x.N, x.C, x.H, x.W)
+	mask := make([]float64, len(x.Data))
+	for i, v := range x.Data {
+		if v > 0 {
+			out.Data[i] = v
+			mask[i] = 1
+		}
+	}
+	r.mask = mask
+	return out
+}
+
+func (r *ReLU) Backward(gradOut *Batch) *Batch {
+	gradIn := NewBatch(gradOut.N, gradOut.C, gradOut.H, gradOut.W)
+	for i := range gradOut.Data {
+		gradIn.Data[i] = gradOut.Data[i] * r.mask[i]
+	}
+	return gradIn
+}
+
+func (r *ReLU) SetTraining(bool) {}
+func (r *ReLU) Params() []*Param { return nil }
+
+// Sigmoid applies the logistic function elementwise.
+type Sigmoid struct {
+	out *Batch
+}
+
+func NewSigmoid() *Sigmoid { return &Sigmoid{} }
+
+func (s *Sigmoid) Forward(x *Batch) *Batch {
+	out := NewBatch(x.N, x.C, x.H, x.W)
+	for i, v := range x.Data {
+		out.Data[i] = 1 / (1 + math.Exp(-v))
+	}
+	s.out = out
+	return out
+}
+
+func (s *Sigmoid) Backward(gradOut *Batch) *Batch {
+	gradIn := NewBatch(gradOut.N, gradOut.C, gradOut.H, gradOut.W)
+	for i := range gradOut.Data {
+		o := s.out.Data[i]
+		gradIn.Data[i] = gradOut.Data[i] * o * (1 - o)
+	}
+	return gradIn
+}
+
+func (s *Sigmoid) SetTraining(bool) {}
+func (s *Sigmoid) Params() []*Param { return nil }
@@ -0,0 +1,77 @@
+package nn
+
+import "math/rand"
+
+// ResidualBlock is a standard ResNet basic block: ConvBN-ReLU-ConvBN with
+// its input summed back in before the final ReLU. Both convs use 3x3
+// kernels, stride 1, and padding 1, so they preserve spatial size and the
+// block requires channels in == channels out.
+type ResidualBlock struct {
+	Conv1 *Conv2D
+	BN1   *BatchNorm
+	Relu1 *ReLU
+	Conv2 *Conv2D
+	BN2   *BatchNorm
+	Relu2 *ReLU
+
+	skip    *Batch
+	sumSize [4]int
+}
+
+// NewResidualBlock builds a ResidualBlock over the given channel count,
+// with weights drawn from rng.
+func NewResidualBlock(channels int, rng *rand.Rand) *ResidualBlock {
+	return &ResidualBlock{
+		Conv1: NewConv2D(channels, channels, 3, 1, 1, rng),
+		BN1:   NewBatchNorm(channels, 0.9, 1e-5),
+		Relu1: NewReLU(),
+		Conv2: NewConv2D(channels, channels, 3, 1, 1, rng),
+		BN2:   NewBatchNorm(channels, 0.9, 1e-5),
+		Relu2: NewReLU(),
+	}
+}
+
+func (r *ResidualBlock) Forward(x *Batch) *Batch {
+	r.skip = x
+	h := r.Relu1.Forward(r.BN1.Forward(r.Conv1.Forward(x)))
+	h = r.BN2.Forward(r.Conv2.Forward(h))
+
+	sum := NewBatch(h.N, h.C, h.H, h.W)
+	for i := range h.Data {
+		sum.Data[i] = h.Data[i] + x.Data[i]
+	}
+	r.sumSize = [4]int{sum.N, sum.C, sum.H, sum.W}
+	return r.Relu2.Forward(sum)
+}
+
+func (r *ResidualBlock) Backward(gradOut *Batch) *Batch {
+	gradSum := r.Relu2.Backward(gradOut)
+
+	// gradSum flows into both branches of the sum: the conv stack, and
+	// directly back to the block's input via the skip connection.
+	gradBranch := r.BN2.Backward(gradSum)
+	gradBranch = r.Conv2.Backward(gradBranch)
+	gradBranch = r.Relu1.Backward(gradBranch)
+	gradBranch = r.BN1.Backward(gradBranch)
+	gradBranch = r.Conv1.Backward(gradBranch)
+
+	gradIn := NewBatch(r.sumSize[0], r.sumSize[1], r.sumSize[2], r.sumSize[3])
+	for i := range gradIn.Data {
+		gradIn.Data[i] = gradBranch.Data[i] + gradSum.Data[i]
+	}
+	return gradIn
+}
+
+func (r *ResidualBlock) SetTraining(training bool) {
+	r.BN1.SetTraining(training)
+	r.BN2.SetTraining(training)
+}
+
+func (r *ResidualBlock) Params() []*Param {
+	var params []*Param
+	params = append(params, r.Conv1.Params()...)
+	params = append(params, r.BN1.Params()...)
+	params = append(params, r.Conv2.Params()...)
+	params = append(params, r.BN2.Params()...)
+	return params
+}
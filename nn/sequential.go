@@ -0,0 +1,41 @@
+package nn
+
+// Sequential chains Layers in order for Forward, and in reverse for
+// Backward, matching the conv/pool/dense topologies in the architectures
+// package.
+type Sequential struct {
+	Layers []Layer
+}
+
+// NewSequential wraps layers into a Sequential.
+func NewSequential(layers ...Layer) *Sequential {
+	return &Sequential{Layers: layers}
+}
+
+func (s *Sequential) Forward(x *Batch) *Batch {
+	for _, l := range s.Layers {
+		x = l.Forward(x)
+	}
+	return x
+}
+
+func (s *Sequential) Backward(gradOut *Batch) *Batch {
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		gradOut = s.Layers[i].Backward(gradOut)
+	}
+	return gradOut
+}
+
+func (s *Sequential) SetTraining(training bool) {
+	for _, l := range s.Layers {
+		l.SetTraining(training)
+	}
+}
+
+func (s *Sequential) Params() []*Param {
+	var params []*Param
+	for _, l := range s.Layers {
+		params = append(params, l.Params()...)
+	}
+	return params
+}